@@ -0,0 +1,6 @@
+// Package client holds the oapi-codegen output generated from
+// /openapi.yaml, plus this file which drives regeneration. Run
+// `go generate ./...` (or `make generate`) after editing the spec.
+package client
+
+//go:generate go run github.com/deepmap/oapi-codegen/v2/cmd/oapi-codegen@v2.2.0 --config=cfg.yaml ../../openapi.yaml
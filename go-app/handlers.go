@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/client"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/config"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/middleware"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/render"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/store"
+)
+
+// patientServer implements client.ServerInterface against a store.Store. Its
+// method set is checked against the generated interface at compile time
+// below, so handwritten routes can't silently drift from openapi.yaml.
+type patientServer struct {
+	store       store.Store
+	cfgHandler  *config.Handler
+	rateLimiter *middleware.RateLimiter
+}
+
+var _ client.ServerInterface = (*patientServer)(nil)
+
+func (s *patientServer) ListPatients(w http.ResponseWriter, r *http.Request, params client.ListPatientsParams) {
+	opts, err := listOptionsFromParams(params)
+	if err != nil {
+		writeError(w, r, newResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := s.store.List(r.Context(), opts)
+	if err != nil {
+		writeError(w, r, newResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	setPaginationLinks(w, r, opts, result)
+	writeJSON(w, r, http.StatusOK, Response{
+		Code: 200, Status: "Ok",
+		Data: result.Data, Total: result.Total, NextCursor: result.NextCursor,
+	})
+}
+
+// listOptionsFromParams maps the typed GET /patients query parameters onto
+// a store.ListOptions:
+//
+//	Q                            substring match against fullname/address
+//	Sex                          exact match
+//	BirthdateFrom, BirthdateTo
+//	Sort                         comma-separated fields, "-" prefix for desc
+//	Limit, Offset                classic pagination
+//	After                        cursor pagination, takes precedence over offset
+func listOptionsFromParams(params client.ListPatientsParams) (store.ListOptions, error) {
+	var opts store.ListOptions
+	if params.Q != nil {
+		opts.Query = *params.Q
+	}
+	if params.Sex != nil {
+		opts.Sex = *params.Sex
+	}
+	if params.BirthdateFrom != nil {
+		opts.BirthdateFrom = *params.BirthdateFrom
+	}
+	if params.BirthdateTo != nil {
+		opts.BirthdateTo = *params.BirthdateTo
+	}
+
+	if params.Sort != nil {
+		for _, field := range strings.Split(*params.Sort, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			desc := strings.HasPrefix(field, "-")
+			opts.Sort = append(opts.Sort, store.SortField{Field: strings.TrimPrefix(field, "-"), Desc: desc})
+		}
+	}
+
+	if params.Limit != nil {
+		if *params.Limit < 0 {
+			return store.ListOptions{}, fmt.Errorf("invalid limit %d", *params.Limit)
+		}
+		opts.Limit = *params.Limit
+	}
+	if params.Offset != nil {
+		if *params.Offset < 0 {
+			return store.ListOptions{}, fmt.Errorf("invalid offset %d", *params.Offset)
+		}
+		opts.Offset = *params.Offset
+	}
+	if params.After != nil {
+		if *params.After < 0 {
+			return store.ListOptions{}, fmt.Errorf("invalid after %d", *params.After)
+		}
+		opts.After = *params.After
+	}
+	return opts, nil
+}
+
+// setPaginationLinks sets rel="next"/rel="prev" Link headers so clients can
+// page through results the way swagger-ui and similar tools expect, without
+// reconstructing query strings themselves.
+func setPaginationLinks(w http.ResponseWriter, r *http.Request, opts store.ListOptions, result store.ListResult) {
+	var links []string
+
+	if result.NextCursor != "" {
+		next := cloneQuery(r.URL.Query())
+		next.Del("after")
+		next.Del("offset")
+		next.Set("after", result.NextCursor)
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+	if opts.After == 0 && opts.Limit > 0 && opts.Offset > 0 {
+		prevOffset := opts.Offset - opts.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		prev := cloneQuery(r.URL.Query())
+		prev.Set("offset", strconv.Itoa(prevOffset))
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="prev"`, r.URL.Path, prev.Encode()))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func cloneQuery(q url.Values) url.Values {
+	out := make(url.Values, len(q))
+	for k, v := range q {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+func (s *patientServer) CreatePatient(w http.ResponseWriter, r *http.Request) {
+	var p Patient
+	if err := render.Decode(r, &p); err != nil {
+		writeError(w, r, newResponseError(http.StatusBadRequest, "invalid request body"))
+		return
+	}
+	if strings.TrimSpace(p.Fullname) == "" || strings.TrimSpace(p.Sex) == "" || strings.TrimSpace(p.Birthdate) == "" || strings.TrimSpace(p.Address) == "" {
+		writeError(w, r, newResponseError(http.StatusBadRequest, "fullname, sex, birthdate and address are required"))
+		return
+	}
+	created, err := s.store.Create(r.Context(), p)
+	if err != nil {
+		writeError(w, r, newResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, Response{Code: 201, Status: "Created", Data: created})
+}
+
+func (s *patientServer) GetPatient(w http.ResponseWriter, r *http.Request, id int64) {
+	p, err := s.store.Get(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, r, newResponseError(http.StatusNotFound, "patient not found"))
+		return
+	} else if err != nil {
+		writeError(w, r, newResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	writeJSON(w, r, http.StatusOK, Response{Code: 200, Status: "Ok", Data: p})
+}
+
+func (s *patientServer) UpdatePatient(w http.ResponseWriter, r *http.Request, id int64) {
+	var upd Patient
+	if err := render.Decode(r, &upd); err != nil {
+		writeError(w, r, newResponseError(http.StatusBadRequest, "invalid request body"))
+		return
+	}
+	if strings.TrimSpace(upd.Fullname) == "" && strings.TrimSpace(upd.Sex) == "" && strings.TrimSpace(upd.Birthdate) == "" && strings.TrimSpace(upd.Address) == "" {
+		writeError(w, r, newResponseError(http.StatusBadRequest, "at least one field required to update"))
+		return
+	}
+	updated, err := s.store.Update(r.Context(), id, upd)
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, r, newResponseError(http.StatusNotFound, "patient not found"))
+		return
+	} else if err != nil {
+		writeError(w, r, newResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	writeJSON(w, r, http.StatusOK, Response{Code: 200, Status: "Ok", Data: updated})
+}
+
+func (s *patientServer) DeletePatient(w http.ResponseWriter, r *http.Request, id int64) {
+	err := s.store.Delete(r.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, r, newResponseError(http.StatusNotFound, "patient not found"))
+		return
+	} else if err != nil {
+		writeError(w, r, newResponseError(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PatchConfig handles PATCH /admin/config: the caller must send the config's
+// current Fingerprint() as If-Match, and the request body as a JSON merge
+// patch. On success it hot-swaps the rate limiter's thresholds (CORS picks
+// up its allow-list change on the next request via cfgHandler.Current). It
+// mutates security-relevant server state, so unlike the other handlers it
+// requires the admin role regardless of method. The response echoes back the
+// resulting config with Redacted so secrets never leave the process, even
+// when a caller only meant to patch an unrelated field like rate_limit_rps.
+func (s *patientServer) PatchConfig(w http.ResponseWriter, r *http.Request, params client.PatchConfigParams) {
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, r, newResponseError(http.StatusUnauthorized, "missing bearer token"))
+		return
+	}
+	if claims.Role != middleware.RoleAdmin {
+		writeError(w, r, newResponseError(http.StatusForbidden, "admin role required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, newResponseError(http.StatusBadRequest, "invalid request body"))
+		return
+	}
+
+	newCfg, err := s.cfgHandler.ApplyPatch(params.IfMatch, body)
+	switch {
+	case errors.Is(err, config.ErrFingerprintMismatch):
+		writeError(w, r, newResponseError(http.StatusPreconditionFailed, err.Error()))
+		return
+	case err != nil:
+		writeError(w, r, newResponseError(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	s.rateLimiter.SetLimits(newCfg.RateLimitRPS, newCfg.RateLimitBurst)
+
+	w.Header().Set("ETag", s.cfgHandler.Fingerprint())
+	writeJSON(w, r, http.StatusOK, Response{Code: 200, Status: "Ok", Data: newCfg.Redacted()})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/client"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/config"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/middleware"
+)
+
+// bearerToken signs a JWT carrying role, using the same HMAC secret authCfg
+// verifies against, so a test can drive a request through middleware.Auth
+// exactly as a real caller would.
+func bearerToken(t *testing.T, secret []byte, role string) string {
+	t.Helper()
+	claims := middleware.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "test-user",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Role: role,
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// TestPatchConfigRequiresAdminRole guards the fix for PATCH /admin/config
+// accepting any authenticated caller: a valid token without the admin role
+// must be rejected before it ever reaches config.Handler.ApplyPatch.
+func TestPatchConfigRequiresAdminRole(t *testing.T) {
+	secret := []byte("test-secret")
+	authCfg := middleware.AuthConfig{HMACSecret: secret}
+	cfgHandler, err := config.Load("")
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	server := &patientServer{cfgHandler: cfgHandler, rateLimiter: middleware.NewRateLimiter(10, 20)}
+
+	handler := middleware.Auth(authCfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.PatchConfig(w, r, client.PatchConfigParams{IfMatch: cfgHandler.Fingerprint()})
+	}))
+
+	tests := []struct {
+		name       string
+		role       string
+		wantStatus int
+	}{
+		{"reader role forbidden", middleware.RoleReader, http.StatusForbidden},
+		{"admin role allowed", middleware.RoleAdmin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/admin/config", bytes.NewReader([]byte(`{}`)))
+			req.Header.Set("Authorization", "Bearer "+bearerToken(t, secret, tt.role))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestPatchConfigRedactsSecrets guards the fix for PatchConfig echoing back
+// the live DatabaseURL and JWTHMACSecret: a patch that only touches
+// rate_limit_rps must not leak either in the response body, and must not be
+// able to change them either, since neither is actually re-read after
+// startup.
+func TestPatchConfigRedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	seed := []byte(`{"database_url":"postgres://user:hunter2@db/patients","jwt_hmac_secret":"super-secret-hmac-key","rate_limit_rps":10,"rate_limit_burst":20}`)
+	if err := os.WriteFile(path, seed, 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfgHandler, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+
+	secret := []byte("test-secret")
+	authCfg := middleware.AuthConfig{HMACSecret: secret}
+	server := &patientServer{cfgHandler: cfgHandler, rateLimiter: middleware.NewRateLimiter(10, 20)}
+	handler := middleware.Auth(authCfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.PatchConfig(w, r, client.PatchConfigParams{IfMatch: cfgHandler.Fingerprint()})
+	}))
+
+	req := httptest.NewRequest(http.MethodPatch, "/admin/config", strings.NewReader(`{"rate_limit_rps":99}`))
+	req.Header.Set("Authorization", "Bearer "+bearerToken(t, secret, middleware.RoleAdmin))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "hunter2") || strings.Contains(rec.Body.String(), "super-secret-hmac-key") {
+		t.Fatalf("response leaked a secret: %s", rec.Body.String())
+	}
+
+	if got := cfgHandler.Current(); got.DatabaseURL != "postgres://user:hunter2@db/patients" || got.JWTHMACSecret != "super-secret-hmac-key" {
+		t.Fatalf("patch changed a field it should ignore: %+v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/imports"
+)
+
+// registerImportRoutes wires the resumable bulk-import upload protocol onto
+// mux: POST starts a session, PATCH appends a byte range, PUT commits it.
+// This mirrors the Docker registry blob-upload flow so large NDJSON/CSV
+// migrations can resume after a dropped connection instead of restarting.
+func registerImportRoutes(mux *http.ServeMux, mgr *imports.Manager) {
+	mux.HandleFunc("/patients/imports", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, r, newResponseError(http.StatusMethodNotAllowed, "method not allowed"))
+			return
+		}
+		sess, err := mgr.Start(r.Context())
+		if err != nil {
+			writeError(w, r, newResponseError(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		w.Header().Set("Location", "/patients/imports/"+sess.ID)
+		w.Header().Set("Range", "0-0")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	mux.HandleFunc("/patients/imports/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/patients/imports/"), "/")
+		if id == "" {
+			writeError(w, r, newResponseError(http.StatusBadRequest, "missing upload id"))
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPatch:
+			contentRange := r.Header.Get("Content-Range")
+			if contentRange == "" {
+				writeError(w, r, newResponseError(http.StatusBadRequest, "Content-Range header is required"))
+				return
+			}
+			offset, err := mgr.Append(r.Context(), id, contentRange, r.Body)
+			if err != nil {
+				writeImportError(w, r, err)
+				return
+			}
+			w.Header().Set("Location", "/patients/imports/"+id)
+			w.Header().Set("Range", fmt.Sprintf("0-%d", offset-1))
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodPut:
+			digest := r.URL.Query().Get("digest")
+			results, err := mgr.Commit(r.Context(), id, digest)
+			if err != nil {
+				writeImportError(w, r, err)
+				return
+			}
+			writeJSON(w, r, http.StatusCreated, Response{Code: 201, Status: "Created", Data: results})
+		default:
+			writeError(w, r, newResponseError(http.StatusMethodNotAllowed, "method not allowed"))
+		}
+	})
+}
+
+func writeImportError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, imports.ErrSessionNotFound):
+		writeError(w, r, newResponseError(http.StatusNotFound, err.Error()))
+	case errors.Is(err, imports.ErrRangeMismatch), errors.Is(err, imports.ErrDigestMismatch):
+		writeError(w, r, newResponseError(http.StatusBadRequest, err.Error()))
+	default:
+		writeError(w, r, newResponseError(http.StatusInternalServerError, err.Error()))
+	}
+}
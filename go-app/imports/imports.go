@@ -0,0 +1,368 @@
+// Package imports implements a resumable, chunked bulk-import upload for
+// patients, modeled on the Docker registry blob upload protocol: a client
+// starts a session to obtain an upload id, PATCHes successive byte ranges to
+// a server-side staging file, then PUTs a digest to commit it. This lets a
+// large NDJSON/CSV migration survive a dropped connection without resending
+// bytes the server already has.
+package imports
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/store"
+)
+
+var (
+	// ErrSessionNotFound is returned for an unknown or already-committed
+	// upload id.
+	ErrSessionNotFound = errors.New("imports: upload session not found")
+	// ErrRangeMismatch is returned when a PATCH's Content-Range doesn't
+	// start where the server's current offset left off.
+	ErrRangeMismatch = errors.New("imports: content-range does not match current offset")
+	// ErrDigestMismatch is returned when the digest given to Commit doesn't
+	// match the staged file's actual sha256.
+	ErrDigestMismatch = errors.New("imports: digest does not match uploaded content")
+)
+
+// RowResult is the outcome of importing a single line of the uploaded file.
+type RowResult struct {
+	Line    int            `json:"line"`
+	Patient *store.Patient `json:"patient,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// Session tracks one in-progress upload.
+type Session struct {
+	ID     string
+	offset int64
+	file   *os.File
+}
+
+// Manager creates and tracks upload sessions and commits them into a Store.
+type Manager struct {
+	store  store.Store
+	tmpDir string
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewManager returns a Manager that stages uploads under tmpDir before
+// committing them to the given Store.
+func NewManager(st store.Store, tmpDir string) *Manager {
+	return &Manager{store: st, tmpDir: tmpDir, sessions: make(map[string]*Session)}
+}
+
+// Start opens a new upload session and returns its id.
+func (m *Manager) Start(ctx context.Context) (*Session, error) {
+	if err := os.MkdirAll(m.tmpDir, 0o755); err != nil {
+		return nil, fmt.Errorf("imports: create tmp dir: %w", err)
+	}
+	id := uuid.NewString()
+	f, err := os.Create(filepath.Join(m.tmpDir, id))
+	if err != nil {
+		return nil, fmt.Errorf("imports: create staging file: %w", err)
+	}
+	sess := &Session{ID: id, file: f}
+
+	m.mu.Lock()
+	m.sessions[id] = sess
+	m.mu.Unlock()
+	return sess, nil
+}
+
+// Append validates that contentRange picks up at the session's current
+// offset, writes body to the staging file at that exact position, and
+// returns the new offset. Writes land via WriteAt rather than a sequential
+// append, and a short or failed write truncates the file back to the
+// session's offset, so a client retrying the same Content-Range after a
+// dropped connection always overwrites cleanly instead of corrupting the
+// staged file with leftover bytes from the failed attempt.
+func (m *Manager) Append(ctx context.Context, id, contentRange string, body io.Reader) (int64, error) {
+	sess, err := m.get(id)
+	if err != nil {
+		return 0, err
+	}
+
+	start, end, err := parseContentRange(contentRange)
+	if err != nil {
+		return 0, err
+	}
+	if start != sess.offset {
+		return 0, ErrRangeMismatch
+	}
+
+	n, err := writeChunkAt(sess.file, start, body)
+	if err != nil {
+		_ = sess.file.Truncate(start)
+		return 0, fmt.Errorf("imports: write chunk: %w", err)
+	}
+	if end > 0 && n != end-start+1 {
+		_ = sess.file.Truncate(start)
+		return 0, fmt.Errorf("%w: wrote %d bytes, range declared %d", ErrRangeMismatch, n, end-start+1)
+	}
+
+	sess.offset = start + n
+	return sess.offset, nil
+}
+
+// writeChunkAt copies r into f starting at offset off, using explicit
+// positioned writes so it never depends on (or disturbs) f's current seek
+// position.
+func writeChunkAt(f *os.File, off int64, r io.Reader) (int64, error) {
+	var buf [32 * 1024]byte
+	var written int64
+	for {
+		nr, rerr := r.Read(buf[:])
+		if nr > 0 {
+			if _, werr := f.WriteAt(buf[:nr], off+written); werr != nil {
+				return written, werr
+			}
+			written += int64(nr)
+		}
+		if rerr == io.EOF {
+			return written, nil
+		}
+		if rerr != nil {
+			return written, rerr
+		}
+	}
+}
+
+// Commit verifies digest against the staged file, parses it as NDJSON or
+// CSV (detected from the first non-blank line), and bulk-inserts every row
+// into the Store inside a single transaction where the driver supports it.
+// On success it returns one RowResult per data row (blank lines and a CSV
+// header row get none), including rows that failed validation, so the
+// caller can report per-row success/error back to the client.
+func (m *Manager) Commit(ctx context.Context, id, digest string) ([]RowResult, error) {
+	sess, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	defer m.forget(id)
+
+	if err := sess.file.Close(); err != nil {
+		return nil, fmt.Errorf("imports: close staging file: %w", err)
+	}
+	path := sess.file.Name()
+	defer os.Remove(path)
+
+	if err := verifyDigest(path, digest); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("imports: reopen staging file: %w", err)
+	}
+	defer f.Close()
+
+	patients, results, err := parseRows(f)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := m.bulkCreate(ctx, patients)
+	if err != nil {
+		return nil, err
+	}
+
+	out := 0
+	for i, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		results[i].Patient = &created[out]
+		out++
+	}
+	return results, nil
+}
+
+func (m *Manager) bulkCreate(ctx context.Context, patients []store.Patient) ([]store.Patient, error) {
+	if bc, ok := m.store.(store.BulkCreator); ok {
+		return bc.CreateMany(ctx, patients)
+	}
+	out := make([]store.Patient, 0, len(patients))
+	for _, p := range patients {
+		created, err := m.store.Create(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, created)
+	}
+	return out, nil
+}
+
+func (m *Manager) get(id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sess, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return sess, nil
+}
+
+func (m *Manager) forget(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+}
+
+// parseContentRange parses a "start-end" Content-Range body range, e.g.
+// "0-1023".
+func parseContentRange(s string) (start, end int64, err error) {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("imports: malformed Content-Range %q", s)
+	}
+	start, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("imports: malformed Content-Range %q", s)
+	}
+	end, err = strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("imports: malformed Content-Range %q", s)
+	}
+	return start, end, nil
+}
+
+func verifyDigest(path, digest string) error {
+	want := strings.TrimPrefix(digest, "sha256:")
+	if want == "" {
+		return fmt.Errorf("imports: missing ?digest= query parameter")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	got := hex.EncodeToString(h.Sum(nil))
+	if got != want {
+		return ErrDigestMismatch
+	}
+	return nil
+}
+
+// validatePatient enforces the same required fields as CreatePatient, so a
+// blank or malformed row produces a RowResult error instead of a blank
+// patient record.
+func validatePatient(p store.Patient) error {
+	if strings.TrimSpace(p.Fullname) == "" || strings.TrimSpace(p.Sex) == "" || strings.TrimSpace(p.Birthdate) == "" || strings.TrimSpace(p.Address) == "" {
+		return errors.New("fullname, sex, birthdate and address are required")
+	}
+	return nil
+}
+
+// parseRows detects NDJSON vs. CSV from the first non-blank line and decodes
+// every row into a Patient, recording a RowResult per line so malformed rows
+// don't abort the whole import.
+func parseRows(r io.Reader) ([]store.Patient, []RowResult, error) {
+	buf := bufio.NewReader(r)
+	first, err := buf.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, nil, fmt.Errorf("imports: read upload: %w", err)
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(first)), "{") {
+		return parseNDJSON(buf)
+	}
+	return parseCSV(buf)
+}
+
+func parseNDJSON(r io.Reader) ([]store.Patient, []RowResult, error) {
+	scanner := bufio.NewScanner(r)
+	var patients []store.Patient
+	var results []RowResult
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var p store.Patient
+		if err := json.Unmarshal([]byte(text), &p); err != nil {
+			results = append(results, RowResult{Line: line, Error: err.Error()})
+			continue
+		}
+		if err := validatePatient(p); err != nil {
+			results = append(results, RowResult{Line: line, Error: err.Error()})
+			continue
+		}
+		patients = append(patients, p)
+		results = append(results, RowResult{Line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("imports: scan NDJSON: %w", err)
+	}
+	return patients, results, nil
+}
+
+func parseCSV(r io.Reader) ([]store.Patient, []RowResult, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("imports: read CSV header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+
+	var patients []store.Patient
+	var results []RowResult
+	line := 1
+	for {
+		line++
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			results = append(results, RowResult{Line: line, Error: err.Error()})
+			continue
+		}
+		p := store.Patient{
+			Fullname:  field(record, col, "fullname"),
+			Sex:       field(record, col, "sex"),
+			Birthdate: field(record, col, "birthdate"),
+			Address:   field(record, col, "address"),
+		}
+		if err := validatePatient(p); err != nil {
+			results = append(results, RowResult{Line: line, Error: err.Error()})
+			continue
+		}
+		patients = append(patients, p)
+		results = append(results, RowResult{Line: line})
+	}
+	return patients, results, nil
+}
+
+func field(record []string, col map[string]int, name string) string {
+	i, ok := col[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
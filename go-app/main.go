@@ -1,27 +1,42 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
-	"strings"
-	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/client"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/config"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/imports"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/middleware"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/render"
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/store"
 )
 
-type Patient struct {
-	ID        int64  `json:"id"`
-	Fullname  string `json:"fullname"`
-	Sex       string `json:"sex"`
-	Birthdate string `json:"birthdate"`
-	Address   string `json:"address"`
-}
+type Patient = store.Patient
 
 type Response struct {
-	Code   int         `json:"code"`
-	Status string      `json:"status"`
-	Data   interface{} `json:"data"`
+	Code       int         `json:"code"`
+	Status     string      `json:"status"`
+	Data       interface{} `json:"data"`
+	Total      int         `json:"total,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// CSVRows lets render.Encode stream GET /patients as CSV without a client
+// ever seeing the {code, status, data} envelope, since CSV has no place to
+// put it.
+func (r Response) CSVRows() ([]store.Patient, bool) {
+	list, ok := r.Data.([]store.Patient)
+	return list, ok
 }
 
 type ResponseError struct {
@@ -30,100 +45,11 @@ type ResponseError struct {
 	Error  string `json:"error"`
 }
 
-type Store struct {
-	mu     sync.Mutex
-	users  []Patient
-	nextID int64
-}
-
-func NewStore() *Store {
-	return &Store{users: make([]Patient, 0), nextID: 1}
-}
-
-func (s *Store) List() Response {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	out := make([]Patient, len(s.users))
-	copy(out, s.users)
-	return Response{
-		Code:   200,
-		Status: "Ok",
-		Data:   out,
-	}
-}
-
-func (s *Store) Get(id int64) (Response, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for _, p := range s.users {
-		if p.ID == id {
-			return Response{
-				Code:   200,
-				Status: "Ok",
-				Data:   p,
-			}, true
-		}
-	}
-	return Response{}, false
-}
-
-func (s *Store) Create(p Patient) Response {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	p.ID = s.nextID
-	s.nextID++
-	s.users = append(s.users, p)
-	return Response{
-		Code:   201,
-		Status: "Created",
-		Data:   p,
-	}
-}
-
-func (s *Store) Update(id int64, upd Patient) (Response, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for i := range s.users {
-		if s.users[i].ID == id {
-			if upd.Fullname != "" {
-				s.users[i].Fullname = upd.Fullname
-			}
-			if upd.Sex != "" {
-				s.users[i].Sex = upd.Sex
-			}
-			if upd.Birthdate != "" {
-				s.users[i].Birthdate = upd.Birthdate
-			}
-			if upd.Address != "" {
-				s.users[i].Address = upd.Address
-			}
-			return Response{
-				Code:   200,
-				Status: "Ok",
-				Data:   s.users[i],
-			}, true
-		}
-	}
-	return Response{}, false
-}
-
-func (s *Store) Delete(id int64) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for i := range s.users {
-		if s.users[i].ID == id {
-			s.users = append(s.users[:i], s.users[i+1:]...)
-			return true
-		}
-	}
-	return false
-}
-
 // helpers
-func writeJSON(w http.ResponseWriter, code int, v interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(v)
+func writeJSON(w http.ResponseWriter, r *http.Request, code int, v interface{}) {
+	if err := render.Encode(w, r, code, v); err != nil {
+		log.Printf("render: encode response: %v", err)
+	}
 }
 
 func newResponseError(code int, msg string) ResponseError {
@@ -134,102 +60,62 @@ func newResponseError(code int, msg string) ResponseError {
 	return ResponseError{Code: code, Status: status, Error: msg}
 }
 
-func writeError(w http.ResponseWriter, respErr ResponseError) {
+func writeError(w http.ResponseWriter, r *http.Request, respErr ResponseError) {
 	if respErr.Code == 0 {
 		respErr.Code = http.StatusInternalServerError
 	}
 	if respErr.Status == "" {
 		respErr.Status = http.StatusText(respErr.Code)
 	}
-	writeJSON(w, respErr.Code, respErr)
+	writeJSON(w, r, respErr.Code, respErr)
 }
 
 func main() {
-	store := NewStore()
-	// seed
-	store.Create(Patient{Fullname: "Alice Example", Sex: "F", Birthdate: "1990-01-01", Address: "123 A St"})
-	store.Create(Patient{Fullname: "Bob Example", Sex: "M", Birthdate: "1988-05-05", Address: "456 B Ave"})
+	ctx := context.Background()
 
-	mux := http.NewServeMux()
+	cfgHandler, err := config.Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	cfg := cfgHandler.Current()
 
-	mux.HandleFunc("/patients", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			writeJSON(w, http.StatusOK, store.List())
-			return
-		case http.MethodPost:
-			var p Patient
-			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
-				writeError(w, newResponseError(http.StatusBadRequest, "invalid JSON body"))
-				return
-			}
-			if strings.TrimSpace(p.Fullname) == "" || strings.TrimSpace(p.Sex) == "" || strings.TrimSpace(p.Birthdate) == "" || strings.TrimSpace(p.Address) == "" {
-				writeError(w, newResponseError(http.StatusBadRequest, "fullname, sex, birthdate and address are required"))
-				return
-			}
-			created := store.Create(p)
-			writeJSON(w, http.StatusCreated, created)
-			return
-		default:
-			writeError(w, newResponseError(http.StatusMethodNotAllowed, "method not allowed"))
-			return
-		}
-	})
+	patients, err := store.New(ctx, firstNonEmpty(os.Getenv("DATABASE_URL"), cfg.DatabaseURL))
+	if err != nil {
+		log.Fatalf("store: %v", err)
+	}
+	defer patients.Close()
 
-	mux.HandleFunc("/patients/", func(w http.ResponseWriter, r *http.Request) {
-		// path: /patients/{id}
-		idStr := strings.TrimPrefix(r.URL.Path, "/patients/")
-		idStr = strings.TrimSuffix(idStr, "/")
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil || idStr == "" {
-			writeError(w, newResponseError(http.StatusBadRequest, "invalid id"))
-			return
-		}
+	// seed, but only on a genuinely empty store: persistent drivers
+	// (Postgres/SQLite) keep their data across restarts, and Create has no
+	// existence check, so seeding unconditionally would insert two more
+	// "Alice Example"/"Bob Example" rows on every process start.
+	if existing, err := patients.List(ctx, store.ListOptions{Limit: 1}); err != nil {
+		log.Fatalf("store: %v", err)
+	} else if existing.Total == 0 {
+		patients.Create(ctx, Patient{Fullname: "Alice Example", Sex: "F", Birthdate: "1990-01-01", Address: "123 A St"})
+		patients.Create(ctx, Patient{Fullname: "Bob Example", Sex: "M", Birthdate: "1988-05-05", Address: "456 B Ave"})
+	}
 
-		switch r.Method {
-		case http.MethodGet:
-			p, ok := store.Get(id)
-			if !ok {
-				writeError(w, newResponseError(http.StatusNotFound, "patient not found"))
-				return
-			}
-			writeJSON(w, http.StatusOK, p)
-			return
-		case http.MethodPut:
-			var upd Patient
-			if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
-				writeError(w, newResponseError(http.StatusBadRequest, "invalid JSON body"))
-				return
-			}
-			if strings.TrimSpace(upd.Fullname) == "" && strings.TrimSpace(upd.Sex) == "" && strings.TrimSpace(upd.Birthdate) == "" && strings.TrimSpace(upd.Address) == "" {
-				writeError(w, newResponseError(http.StatusBadRequest, "at least one field required to update"))
-				return
-			}
-			updated, ok := store.Update(id, upd)
-			if !ok {
-				writeError(w, newResponseError(http.StatusNotFound, "patient not found"))
-				return
-			}
-			writeJSON(w, http.StatusOK, updated)
-			return
-		case http.MethodDelete:
-			if ok := store.Delete(id); !ok {
-				writeError(w, newResponseError(http.StatusNotFound, "patient not found"))
-				return
-			}
-			w.WriteHeader(http.StatusNoContent)
-			return
-		default:
-			writeError(w, newResponseError(http.StatusMethodNotAllowed, "method not allowed"))
-			return
-		}
+	authCfg, err := authConfig(cfg)
+	if err != nil {
+		log.Fatalf("auth: %v", err)
+	}
+	rpsEnv, burstEnv := rateLimitEnv("RATE_LIMIT_RPS", cfg.RateLimitRPS), rateLimitEnv("RATE_LIMIT_BURST", float64(cfg.RateLimitBurst))
+	rateLimiter := middleware.NewRateLimiter(rpsEnv, int(burstEnv))
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	mux := http.NewServeMux()
+	client.HandlerWithOptions(&patientServer{store: patients, cfgHandler: cfgHandler, rateLimiter: rateLimiter}, client.StdHTTPServerOptions{
+		BaseRouter:       mux,
+		ErrorHandlerFunc: paramErrorHandler,
 	})
+	registerImportRoutes(mux, imports.NewManager(patients, filepath.Join(os.TempDir(), "patients-imports")))
 
 	// serve OpenAPI spec file
 	mux.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
 		data, err := os.ReadFile("../openapi.yaml")
 		if err != nil {
-			writeError(w, newResponseError(http.StatusNotFound, "openapi spec not found"))
+			writeError(w, r, newResponseError(http.StatusNotFound, "openapi spec not found"))
 			return
 		}
 		w.Header().Set("Content-Type", "application/x-yaml")
@@ -265,23 +151,114 @@ func main() {
 		w.Write([]byte(html))
 	})
 
-	// wrap mux with CORS middleware to allow requests from any origin
-	handler := corsMiddleware(mux)
+	// corsMiddleware stays outermost so preflight OPTIONS requests are
+	// answered before request-id/auth/rate-limit run, and it reads the
+	// allow-list live off cfgHandler so PATCH /admin/config hot-swaps it.
+	// Auth runs before the rate limiter so RateLimiter.keyFor sees the
+	// verified claims and can key by JWT subject instead of always
+	// falling back to client IP.
+	handler := middleware.Chain(mux,
+		corsMiddleware(cfgHandler),
+		middleware.RequestID,
+		middleware.AccessLog(logger),
+		middleware.Auth(authCfg),
+		rateLimiter.Middleware,
+	)
 
-	log.Println("Server listening on :8391")
-	log.Fatal(http.ListenAndServe(":8391", handler))
+	addr := firstNonEmpty(os.Getenv("LISTEN_ADDR"), cfg.ListenAddr, ":8391")
+	log.Printf("Server listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, handler))
 }
 
-// corsMiddleware sets permissive CORS headers and handles preflight requests
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
+// firstNonEmpty returns the first non-empty string in vals, or "" if all are
+// empty.
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
 		}
-		next.ServeHTTP(w, r)
-	})
+	}
+	return ""
+}
+
+// authConfig builds a middleware.AuthConfig, preferring explicit env vars
+// over the loaded config so existing deployments keep working unchanged.
+func authConfig(cfg config.Config) (middleware.AuthConfig, error) {
+	envCfg, err := middleware.AuthConfigFromEnv()
+	if err != nil {
+		return middleware.AuthConfig{}, err
+	}
+	if envCfg.Enabled() {
+		return envCfg, nil
+	}
+	var fileCfg middleware.AuthConfig
+	if cfg.JWTHMACSecret != "" {
+		fileCfg.HMACSecret = []byte(cfg.JWTHMACSecret)
+	}
+	if cfg.JWTRSAPublicKey != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.JWTRSAPublicKey))
+		if err != nil {
+			return middleware.AuthConfig{}, fmt.Errorf("config: parse jwt_rsa_public_key: %w", err)
+		}
+		fileCfg.RSAPublicKey = key
+	}
+	return fileCfg, nil
+}
+
+// rateLimitEnv reads a positive float from the named env var, falling back
+// to def when unset or invalid.
+func rateLimitEnv(name string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(name), 64)
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
+
+// corsMiddleware sets CORS headers off cfgHandler's live allow-list and
+// answers preflight requests, so PATCH /admin/config changes take effect on
+// the very next request with no restart.
+func corsMiddleware(cfgHandler *config.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if allowed := matchOrigin(cfgHandler.Current().CORSOrigins, r.Header.Get("Origin")); allowed != "" {
+				w.Header().Set("Access-Control-Allow-Origin", allowed)
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// matchOrigin returns the Access-Control-Allow-Origin value for origin given
+// the configured allow-list, or "" if origin isn't allowed.
+func matchOrigin(allowed []string, origin string) string {
+	for _, o := range allowed {
+		if o == "*" {
+			return "*"
+		}
+		if o == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
+// paramErrorHandler renders the generated ServerInterfaceWrapper's parameter
+// errors (missing/malformed headers or query params) in the same JSON
+// envelope as handler-level errors. A missing If-Match specifically keeps
+// its 428 status, matching the semantics openapi.yaml documents for
+// PATCH /admin/config.
+func paramErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	var missingHeader *client.RequiredHeaderError
+	if errors.As(err, &missingHeader) && missingHeader.ParamName == "If-Match" {
+		writeError(w, r, newResponseError(http.StatusPreconditionRequired, "If-Match header is required"))
+		return
+	}
+	writeError(w, r, newResponseError(http.StatusBadRequest, err.Error()))
 }
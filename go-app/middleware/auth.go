@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Roles recognized in the JWT's "role" claim.
+const (
+	RoleAdmin  = "admin"
+	RoleReader = "reader"
+)
+
+// Claims is the JWT payload the auth middleware expects. Role gates
+// DELETE requests; everything else is left to the handlers.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+}
+
+// AuthConfig holds the key material used to verify bearer tokens. Either or
+// both of HMACSecret and RSAPublicKey may be set; each token is verified
+// with the one matching its signing algorithm (HS256 or RS256 respectively).
+type AuthConfig struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+}
+
+// Enabled reports whether any key material was configured. When it isn't,
+// Auth runs as a no-op so the server stays usable in local development
+// without a token.
+func (c AuthConfig) Enabled() bool {
+	return len(c.HMACSecret) > 0 || c.RSAPublicKey != nil
+}
+
+type claimsContextKey int
+
+const claimsKey claimsContextKey = 0
+
+// ClaimsFromContext returns the verified claims stashed by Auth, if a
+// request passed through it with a valid bearer token.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*Claims)
+	return claims, ok
+}
+
+// Auth verifies a JWT bearer token on every non-GET/HEAD/OPTIONS request,
+// leaving reads public. DELETE additionally requires the "admin" role. When
+// cfg has no key material configured, Auth passes every request through
+// unchecked.
+func Auth(cfg AuthConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tokenString := bearerToken(r)
+			if tokenString == "" {
+				writeError(w, http.StatusUnauthorized, "missing bearer token")
+				return
+			}
+
+			claims := &Claims{}
+			_, err := jwt.ParseWithClaims(tokenString, claims, cfg.keyFunc)
+			if err != nil {
+				writeError(w, http.StatusUnauthorized, "invalid bearer token")
+				return
+			}
+
+			if r.Method == http.MethodDelete && claims.Role != RoleAdmin {
+				writeError(w, http.StatusForbidden, "admin role required")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey, claims)))
+		})
+	}
+}
+
+// AuthConfigFromEnv reads JWT_HMAC_SECRET (raw string) and/or
+// JWT_RSA_PUBLIC_KEY (PEM-encoded) to build an AuthConfig. Either, both, or
+// neither may be set; see AuthConfig.Enabled.
+func AuthConfigFromEnv() (AuthConfig, error) {
+	var cfg AuthConfig
+	if secret := os.Getenv("JWT_HMAC_SECRET"); secret != "" {
+		cfg.HMACSecret = []byte(secret)
+	}
+	if pemKey := os.Getenv("JWT_RSA_PUBLIC_KEY"); pemKey != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pemKey))
+		if err != nil {
+			return AuthConfig{}, fmt.Errorf("middleware: parse JWT_RSA_PUBLIC_KEY: %w", err)
+		}
+		cfg.RSAPublicKey = key
+	}
+	return cfg, nil
+}
+
+func (c AuthConfig) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if c.HMACSecret == nil {
+			return nil, fmt.Errorf("middleware: no HMAC secret configured")
+		}
+		return c.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		if c.RSAPublicKey == nil {
+			return nil, fmt.Errorf("middleware: no RSA public key configured")
+		}
+		return c.RSAPublicKey, nil
+	default:
+		return nil, fmt.Errorf("middleware: unsupported signing method %v", token.Header["alg"])
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, prefix))
+}
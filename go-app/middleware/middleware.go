@@ -0,0 +1,32 @@
+// Package middleware provides the cross-cutting HTTP stack wrapped around
+// the patients mux: request-id propagation, structured access logging, JWT
+// bearer auth, and a token-bucket rate limiter.
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Chain wraps h with mws in order, so the first middleware listed is the
+// outermost — the first to see a request and the last to see its response.
+func Chain(h http.Handler, mws ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// errorResponse mirrors main.ResponseError's wire shape so auth and
+// rate-limit rejections look identical to handler-level errors to clients.
+type errorResponse struct {
+	Code   int    `json:"code"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorResponse{Code: code, Status: http.StatusText(code), Error: msg})
+}
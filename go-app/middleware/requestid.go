@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// RequestIDHeader is the header a request-id is read from and echoed on.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestID reuses an inbound X-Request-Id or mints a new one, echoes it on
+// the response, and stores it in the request context for downstream
+// middleware (notably AccessLog) and handlers to read.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+// RequestIDFromContext returns the request id stashed by RequestID, or ""
+// if none is present (e.g. in a test calling a handler directly).
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,147 @@
+// Package render performs content negotiation for HTTP handlers: decoding
+// request bodies and encoding response bodies as JSON, XML or CSV based on
+// the Content-Type and Accept headers, mirroring how frameworks like Echo
+// dispatch on a content-type prefix.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/BioSystems-Indonesia/full-stack-developer-test/go-app/store"
+)
+
+// Supported content types.
+const (
+	MIMEJSON = "application/json"
+	MIMEXML  = "application/xml"
+	MIMEXML2 = "text/xml"
+	MIMECSV  = "text/csv"
+)
+
+// Decode reads r's body into v according to its Content-Type header,
+// defaulting to JSON when the header is absent or unrecognized. v must be a
+// *store.Patient for CSV bodies.
+func Decode(r *http.Request, v interface{}) error {
+	switch contentType(r.Header.Get("Content-Type")) {
+	case MIMEXML, MIMEXML2:
+		return xml.NewDecoder(r.Body).Decode(v)
+	case MIMECSV:
+		p, ok := v.(*store.Patient)
+		if !ok {
+			return fmt.Errorf("render: CSV decode only supports *store.Patient, got %T", v)
+		}
+		return decodeCSVPatient(r.Body, p)
+	default:
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+}
+
+// csvSource is implemented by response envelopes (e.g. main.Response) that
+// can offer up their payload as a patient list for tabular export.
+type csvSource interface {
+	CSVRows() ([]store.Patient, bool)
+}
+
+// Encode writes v to w in the format requested by r's Accept header,
+// defaulting to JSON. CSV is only meaningful for a []store.Patient, or a v
+// that unwraps to one via csvSource (e.g. GET /patients); other shapes fall
+// back to JSON even when CSV is requested, since an error envelope has no
+// tabular form.
+func Encode(w http.ResponseWriter, r *http.Request, code int, v interface{}) error {
+	switch contentType(r.Header.Get("Accept")) {
+	case MIMEXML, MIMEXML2:
+		w.Header().Set("Content-Type", MIMEXML)
+		w.WriteHeader(code)
+		return xml.NewEncoder(w).Encode(v)
+	case MIMECSV:
+		if patients, ok := csvRows(v); ok {
+			w.Header().Set("Content-Type", MIMECSV)
+			w.WriteHeader(code)
+			return encodeCSVPatients(w, patients)
+		}
+		fallthrough
+	default:
+		w.Header().Set("Content-Type", MIMEJSON)
+		w.WriteHeader(code)
+		return json.NewEncoder(w).Encode(v)
+	}
+}
+
+func csvRows(v interface{}) ([]store.Patient, bool) {
+	if patients, ok := v.([]store.Patient); ok {
+		return patients, true
+	}
+	if src, ok := v.(csvSource); ok {
+		return src.CSVRows()
+	}
+	return nil, false
+}
+
+// contentType strips parameters (e.g. "; charset=utf-8") and returns the
+// bare media type, defaulting to JSON for empty or unparsable headers.
+func contentType(header string) string {
+	if header == "" {
+		return MIMEJSON
+	}
+	mt, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return MIMEJSON
+	}
+	return mt
+}
+
+var csvHeader = []string{"id", "fullname", "sex", "birthdate", "address"}
+
+func encodeCSVPatients(w io.Writer, patients []store.Patient) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, p := range patients {
+		row := []string{strconv.FormatInt(p.ID, 10), p.Fullname, p.Sex, p.Birthdate, p.Address}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// decodeCSVPatient reads a header row followed by exactly one data row and
+// populates p from it, used when a client POSTs/PUTs a single patient as
+// CSV instead of JSON.
+func decodeCSVPatient(r io.Reader, p *store.Patient) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("render: read CSV header: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[name] = i
+	}
+	record, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("render: read CSV row: %w", err)
+	}
+	if i, ok := col["fullname"]; ok && i < len(record) {
+		p.Fullname = record[i]
+	}
+	if i, ok := col["sex"]; ok && i < len(record) {
+		p.Sex = record[i]
+	}
+	if i, ok := col["birthdate"]; ok && i < len(record) {
+		p.Birthdate = record[i]
+	}
+	if i, ok := col["address"]; ok && i < len(record) {
+		p.Address = record[i]
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// New selects a Store driver based on databaseURL's scheme:
+//
+//	""                   -> in-memory store (data lost on restart)
+//	"postgres://..."      -> Postgres via pgx
+//	"postgresql://..."    -> Postgres via pgx
+//	"sqlite://..." / "file:..." -> SQLite
+//
+// It is the single entry point main uses to build the Store so that adding
+// a new driver never requires touching main.go.
+func New(ctx context.Context, databaseURL string) (Store, error) {
+	switch {
+	case databaseURL == "":
+		return NewMemory(), nil
+	case strings.HasPrefix(databaseURL, "postgres://"), strings.HasPrefix(databaseURL, "postgresql://"):
+		return NewPostgres(ctx, databaseURL)
+	case strings.HasPrefix(databaseURL, "sqlite://"):
+		return NewSQLite(ctx, strings.TrimPrefix(databaseURL, "sqlite://"))
+	case strings.HasPrefix(databaseURL, "file:"):
+		return NewSQLite(ctx, databaseURL)
+	default:
+		return nil, fmt.Errorf("store: unrecognized DATABASE_URL scheme in %q", databaseURL)
+	}
+}
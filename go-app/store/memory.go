@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// memoryStore is the in-memory driver, used when DATABASE_URL is unset. It
+// keeps patients in a map keyed by id so Get/Update/Delete are O(1) instead
+// of scanning a slice.
+type memoryStore struct {
+	mu     sync.RWMutex
+	byID   map[int64]Patient
+	nextID int64
+}
+
+// NewMemory returns a Store backed by an in-process map. Data does not
+// survive process restarts.
+func NewMemory() Store {
+	return &memoryStore{byID: make(map[int64]Patient), nextID: 1}
+}
+
+func (s *memoryStore) List(ctx context.Context, opts ListOptions) (ListResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := make([]Patient, 0, len(s.byID))
+	for _, p := range s.byID {
+		if matchesFilters(p, opts) {
+			matched = append(matched, p)
+		}
+	}
+	sortPatients(matched, opts.Sort)
+
+	total := len(matched)
+	if opts.After > 0 {
+		matched = afterCursor(matched, opts.After)
+	} else if opts.Offset > 0 {
+		if opts.Offset >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[opts.Offset:]
+		}
+	}
+
+	nextCursor := ""
+	if opts.Limit > 0 && len(matched) > opts.Limit {
+		matched = matched[:opts.Limit]
+		nextCursor = strconv.FormatInt(matched[len(matched)-1].ID, 10)
+	}
+
+	return ListResult{Data: matched, Total: total, NextCursor: nextCursor}, nil
+}
+
+func matchesFilters(p Patient, opts ListOptions) bool {
+	if opts.Query != "" {
+		q := strings.ToLower(opts.Query)
+		if !strings.Contains(strings.ToLower(p.Fullname), q) && !strings.Contains(strings.ToLower(p.Address), q) {
+			return false
+		}
+	}
+	if opts.Sex != "" && p.Sex != opts.Sex {
+		return false
+	}
+	if opts.BirthdateFrom != "" && p.Birthdate < opts.BirthdateFrom {
+		return false
+	}
+	if opts.BirthdateTo != "" && p.Birthdate > opts.BirthdateTo {
+		return false
+	}
+	return true
+}
+
+func sortPatients(patients []Patient, fields []SortField) {
+	if len(fields) == 0 {
+		sort.Slice(patients, func(i, j int) bool { return patients[i].ID < patients[j].ID })
+		return
+	}
+	sort.Slice(patients, func(i, j int) bool {
+		for _, f := range fields {
+			less, equal := compareField(patients[i], patients[j], f)
+			if !equal {
+				return less
+			}
+		}
+		return patients[i].ID < patients[j].ID
+	})
+}
+
+func compareField(a, b Patient, f SortField) (less bool, equal bool) {
+	var av, bv string
+	switch f.Field {
+	case "fullname":
+		av, bv = a.Fullname, b.Fullname
+	case "birthdate":
+		av, bv = a.Birthdate, b.Birthdate
+	case "sex":
+		av, bv = a.Sex, b.Sex
+	case "address":
+		av, bv = a.Address, b.Address
+	default:
+		av, bv = a.Fullname, b.Fullname
+	}
+	if av == bv {
+		return false, true
+	}
+	if f.Desc {
+		return av > bv, false
+	}
+	return av < bv, false
+}
+
+// afterCursor returns the patients whose ID is greater than after, keeping
+// their existing order, the same rows the SQL drivers' "id > after" clause
+// selects.
+func afterCursor(patients []Patient, after int64) []Patient {
+	out := make([]Patient, 0, len(patients))
+	for _, p := range patients {
+		if p.ID > after {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (s *memoryStore) Get(ctx context.Context, id int64) (Patient, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.byID[id]
+	if !ok {
+		return Patient{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (s *memoryStore) Create(ctx context.Context, p Patient) (Patient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.ID = s.nextID
+	s.nextID++
+	s.byID[p.ID] = p
+	return p, nil
+}
+
+func (s *memoryStore) Update(ctx context.Context, id int64, upd Patient) (Patient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.byID[id]
+	if !ok {
+		return Patient{}, ErrNotFound
+	}
+	if upd.Fullname != "" {
+		p.Fullname = upd.Fullname
+	}
+	if upd.Sex != "" {
+		p.Sex = upd.Sex
+	}
+	if upd.Birthdate != "" {
+		p.Birthdate = upd.Birthdate
+	}
+	if upd.Address != "" {
+		p.Address = upd.Address
+	}
+	s.byID[id] = p
+	return p, nil
+}
+
+func (s *memoryStore) CreateMany(ctx context.Context, patients []Patient) ([]Patient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Patient, len(patients))
+	for i, p := range patients {
+		p.ID = s.nextID
+		s.nextID++
+		s.byID[p.ID] = p
+		out[i] = p
+	}
+	return out, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byID[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.byID, id)
+	return nil
+}
+
+func (s *memoryStore) Close() error { return nil }
+
+var _ BulkCreator = (*memoryStore)(nil)
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sqlStore is the shared database/sql-backed implementation used by both the
+// Postgres and SQLite drivers. The drivers differ in the placeholder syntax
+// used to build queries (placeholder), in how an inserted row's id is read
+// back (returningID) and in the row lock Update takes (lockClause).
+type sqlStore struct {
+	db          *sql.DB
+	placeholder func(n int) string
+	// returningID is true for drivers (Postgres) whose sql.Driver does not
+	// support LastInsertId and instead need "RETURNING id" on INSERT.
+	returningID bool
+	// lockClause is appended to the SELECT ... FOR UPDATE used by Update to
+	// take a row lock for the duration of the transaction. SQLite has no
+	// row-level locking so it is left empty there.
+	lockClause string
+}
+
+// dollarPlaceholder renders Postgres-style "$1, $2, ..." placeholders.
+func dollarPlaceholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+// questionPlaceholder renders SQLite/MySQL-style "?" placeholders.
+func questionPlaceholder(n int) string { return "?" }
+
+func (s *sqlStore) List(ctx context.Context, opts ListOptions) (ListResult, error) {
+	// Total ignores pagination (see ListResult.Total), so the count query's
+	// WHERE must apply the filters only, not the After cursor - otherwise
+	// it would count just the rows past the cursor instead of every row
+	// matching the filters.
+	countWhere, countArgs := s.buildWhere(opts, false)
+	var total int
+	countQuery := "SELECT COUNT(*) FROM patients" + countWhere
+	if err := s.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return ListResult{}, fmt.Errorf("count: %w", err)
+	}
+
+	where, args := s.buildWhere(opts, true)
+	query := "SELECT id, fullname, sex, birthdate, address FROM patients" + where + s.buildOrderBy(opts.Sort)
+
+	pageArgs := append([]interface{}{}, args...)
+	limit := opts.Limit
+	if limit > 0 {
+		// fetch one extra row so we can tell whether a next page exists
+		query += fmt.Sprintf(" LIMIT %s", s.placeholder(len(pageArgs)+1))
+		pageArgs = append(pageArgs, limit+1)
+	}
+	if opts.After == 0 && opts.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %s", s.placeholder(len(pageArgs)+1))
+		pageArgs = append(pageArgs, opts.Offset)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, pageArgs...)
+	if err != nil {
+		return ListResult{}, fmt.Errorf("list: %w", err)
+	}
+	defer rows.Close()
+
+	out := make([]Patient, 0)
+	for rows.Next() {
+		var p Patient
+		if err := rows.Scan(&p.ID, &p.Fullname, &p.Sex, &p.Birthdate, &p.Address); err != nil {
+			return ListResult{}, err
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return ListResult{}, err
+	}
+
+	nextCursor := ""
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+		nextCursor = strconv.FormatInt(out[len(out)-1].ID, 10)
+	}
+
+	return ListResult{Data: out, Total: total, NextCursor: nextCursor}, nil
+}
+
+// buildWhere returns a " WHERE ..." clause (or "" if no filters apply) plus
+// its positional arguments. includeCursor controls whether opts.After is
+// folded in: the page query needs it, but the COUNT(*) query must not, since
+// Total is documented to ignore pagination (including the cursor).
+func (s *sqlStore) buildWhere(opts ListOptions, includeCursor bool) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	if opts.Query != "" {
+		like := "%" + opts.Query + "%"
+		clauses = append(clauses, fmt.Sprintf(
+			"(LOWER(fullname) LIKE LOWER(%s) OR LOWER(address) LIKE LOWER(%s))",
+			s.placeholder(len(args)+1), s.placeholder(len(args)+2),
+		))
+		args = append(args, like, like)
+	}
+	if opts.Sex != "" {
+		clauses = append(clauses, fmt.Sprintf("sex = %s", s.placeholder(len(args)+1)))
+		args = append(args, opts.Sex)
+	}
+	if opts.BirthdateFrom != "" {
+		clauses = append(clauses, fmt.Sprintf("birthdate >= %s", s.placeholder(len(args)+1)))
+		args = append(args, opts.BirthdateFrom)
+	}
+	if opts.BirthdateTo != "" {
+		clauses = append(clauses, fmt.Sprintf("birthdate <= %s", s.placeholder(len(args)+1)))
+		args = append(args, opts.BirthdateTo)
+	}
+	if includeCursor && opts.After > 0 {
+		clauses = append(clauses, fmt.Sprintf("id > %s", s.placeholder(len(args)+1)))
+		args = append(args, opts.After)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func (s *sqlStore) buildOrderBy(fields []SortField) string {
+	allowed := map[string]string{"fullname": "fullname", "birthdate": "birthdate", "sex": "sex", "address": "address"}
+	var parts []string
+	for _, f := range fields {
+		col, ok := allowed[f.Field]
+		if !ok {
+			continue
+		}
+		if f.Desc {
+			parts = append(parts, col+" DESC")
+		} else {
+			parts = append(parts, col+" ASC")
+		}
+	}
+	parts = append(parts, "id ASC")
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+func (s *sqlStore) Get(ctx context.Context, id int64) (Patient, error) {
+	query := fmt.Sprintf(`SELECT id, fullname, sex, birthdate, address FROM patients WHERE id = %s`, s.placeholder(1))
+	var p Patient
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Fullname, &p.Sex, &p.Birthdate, &p.Address)
+	if err == sql.ErrNoRows {
+		return Patient{}, ErrNotFound
+	}
+	if err != nil {
+		return Patient{}, err
+	}
+	return p, nil
+}
+
+func (s *sqlStore) Create(ctx context.Context, p Patient) (Patient, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Patient{}, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(
+		`INSERT INTO patients (fullname, sex, birthdate, address) VALUES (%s, %s, %s, %s)`,
+		s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4),
+	)
+
+	var id int64
+	if s.returningID {
+		err = tx.QueryRowContext(ctx, query+" RETURNING id", p.Fullname, p.Sex, p.Birthdate, p.Address).Scan(&id)
+	} else {
+		var res sql.Result
+		res, err = tx.ExecContext(ctx, query, p.Fullname, p.Sex, p.Birthdate, p.Address)
+		if err == nil {
+			id, err = res.LastInsertId()
+		}
+	}
+	if err != nil {
+		return Patient{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Patient{}, err
+	}
+	p.ID = id
+	return p, nil
+}
+
+func (s *sqlStore) Update(ctx context.Context, id int64, upd Patient) (Patient, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Patient{}, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`SELECT fullname, sex, birthdate, address FROM patients WHERE id = %s%s`, s.placeholder(1), s.lockClause)
+	var p Patient
+	p.ID = id
+	err = tx.QueryRowContext(ctx, query, id).Scan(&p.Fullname, &p.Sex, &p.Birthdate, &p.Address)
+	if err == sql.ErrNoRows {
+		return Patient{}, ErrNotFound
+	}
+	if err != nil {
+		return Patient{}, err
+	}
+
+	if upd.Fullname != "" {
+		p.Fullname = upd.Fullname
+	}
+	if upd.Sex != "" {
+		p.Sex = upd.Sex
+	}
+	if upd.Birthdate != "" {
+		p.Birthdate = upd.Birthdate
+	}
+	if upd.Address != "" {
+		p.Address = upd.Address
+	}
+
+	updateQuery := fmt.Sprintf(
+		`UPDATE patients SET fullname = %s, sex = %s, birthdate = %s, address = %s WHERE id = %s`,
+		s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.placeholder(5),
+	)
+	if _, err := tx.ExecContext(ctx, updateQuery, p.Fullname, p.Sex, p.Birthdate, p.Address, id); err != nil {
+		return Patient{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Patient{}, err
+	}
+	return p, nil
+}
+
+func (s *sqlStore) CreateMany(ctx context.Context, patients []Patient) ([]Patient, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(
+		`INSERT INTO patients (fullname, sex, birthdate, address) VALUES (%s, %s, %s, %s)`,
+		s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4),
+	)
+
+	out := make([]Patient, len(patients))
+	for i, p := range patients {
+		var id int64
+		if s.returningID {
+			err = tx.QueryRowContext(ctx, query+" RETURNING id", p.Fullname, p.Sex, p.Birthdate, p.Address).Scan(&id)
+		} else {
+			var res sql.Result
+			res, err = tx.ExecContext(ctx, query, p.Fullname, p.Sex, p.Birthdate, p.Address)
+			if err == nil {
+				id, err = res.LastInsertId()
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i, err)
+		}
+		p.ID = id
+		out[i] = p
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _ BulkCreator = (*sqlStore)(nil)
+
+func (s *sqlStore) Delete(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`DELETE FROM patients WHERE id = %s`, s.placeholder(1))
+	res, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
+
+func (s *sqlStore) Close() error { return s.db.Close() }
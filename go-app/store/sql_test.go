@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestSQLiteStore opens an in-memory SQLite database with the patients
+// schema applied directly (bypassing NewSQLite's on-disk migration path,
+// which assumes the process cwd is go-app/), so sqlStore can be exercised
+// without a real database.
+func newTestSQLiteStore(t *testing.T) *sqlStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file::memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+
+	const schema = `CREATE TABLE patients (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		fullname   TEXT NOT NULL,
+		sex        TEXT NOT NULL,
+		birthdate  TEXT NOT NULL,
+		address    TEXT NOT NULL
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	return &sqlStore{db: db, placeholder: questionPlaceholder}
+}
+
+// TestListTotalIgnoresAfterCursor guards ListResult.Total's documented
+// contract (Total counts every row matching the filters, regardless of
+// pagination) against the After cursor leaking into the COUNT(*) query's
+// WHERE clause, which would make Total count only rows past the cursor.
+func TestListTotalIgnoresAfterCursor(t *testing.T) {
+	s := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.Create(ctx, Patient{Fullname: "Patient", Sex: "F", Birthdate: "1990-01-01", Address: "Addr"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	result, err := s.List(ctx, ListOptions{After: 2})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if result.Total != 5 {
+		t.Fatalf("Total = %d, want 5 (ignoring the After cursor)", result.Total)
+	}
+	if len(result.Data) != 3 {
+		t.Fatalf("len(Data) = %d, want 3 (rows with id > 2)", len(result.Data))
+	}
+}
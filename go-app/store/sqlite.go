@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewSQLite opens a SQLite-backed Store at the given file path (e.g.
+// "/var/lib/patients/data.db", which New passes for a DATABASE_URL of
+// "sqlite:///var/lib/patients/data.db", or "file:data.db") and applies any
+// pending migrations from store/migrations/sqlite.
+func NewSQLite(ctx context.Context, path string) (Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: open: %w", err)
+	}
+	// writes are serialized by sqlStore's use of transactions; restrict to a
+	// single connection so SQLite doesn't return "database is locked" under
+	// concurrent access.
+	db.SetMaxOpenConns(1)
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("sqlite: ping: %w", err)
+	}
+	if err := migrateSQLite(db); err != nil {
+		return nil, fmt.Errorf("sqlite: migrate: %w", err)
+	}
+	return &sqlStore{db: db, placeholder: questionPlaceholder}, nil
+}
+
+func migrateSQLite(db *sql.DB) error {
+	driver, err := migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	if err != nil {
+		return err
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://store/migrations/sqlite", "sqlite3", driver)
+	if err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
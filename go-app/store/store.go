@@ -0,0 +1,85 @@
+// Package store defines the persistence interface for patients and the
+// drivers that implement it (in-memory, Postgres, SQLite).
+package store
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+)
+
+// ErrNotFound is returned by Get, Update and Delete when no patient matches
+// the given id.
+var ErrNotFound = errors.New("patient not found")
+
+// Patient is the domain record persisted by a Store. Tags cover every
+// wire format the API negotiates: JSON (default), XML and CSV.
+type Patient struct {
+	XMLName   xml.Name `json:"-" xml:"patient" csv:"-"`
+	ID        int64    `json:"id" xml:"id" csv:"id"`
+	Fullname  string   `json:"fullname" xml:"fullname" csv:"fullname"`
+	Sex       string   `json:"sex" xml:"sex" csv:"sex"`
+	Birthdate string   `json:"birthdate" xml:"birthdate" csv:"birthdate"`
+	Address   string   `json:"address" xml:"address" csv:"address"`
+}
+
+// SortField orders List results by one Patient field, ascending unless Desc
+// is set.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListOptions controls filtering, sorting and pagination for Store.List.
+// The zero value lists everything, ordered by ID, with no limit.
+type ListOptions struct {
+	// Query substring-matches against Fullname or Address, case-insensitive.
+	Query string
+	// Sex, if non-empty, is matched exactly.
+	Sex string
+	// BirthdateFrom/BirthdateTo bound Birthdate inclusively (lexical
+	// comparison, so dates must stay in YYYY-MM-DD form).
+	BirthdateFrom string
+	BirthdateTo   string
+	Sort          []SortField
+
+	// Limit caps the number of rows returned; 0 means no limit.
+	Limit int
+	// Offset skips this many matching rows before Limit is applied.
+	Offset int
+	// After, when non-zero, switches to cursor pagination: only patients
+	// with ID greater than After are returned (still ordered by Sort, with
+	// ID as the final tiebreaker), and Offset is ignored.
+	After int64
+}
+
+// ListResult is the page of patients matching a ListOptions query, plus
+// enough information for the caller to fetch the next page.
+type ListResult struct {
+	Data []Patient
+	// Total is the count of patients matching the filters, ignoring
+	// pagination.
+	Total int
+	// NextCursor is the ID to pass as After to fetch the next page, or ""
+	// once there are no more matching rows.
+	NextCursor string
+}
+
+// Store is the persistence contract for patients. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	List(ctx context.Context, opts ListOptions) (ListResult, error)
+	Get(ctx context.Context, id int64) (Patient, error)
+	Create(ctx context.Context, p Patient) (Patient, error)
+	Update(ctx context.Context, id int64, upd Patient) (Patient, error)
+	Delete(ctx context.Context, id int64) error
+	Close() error
+}
+
+// BulkCreator is an optional capability: drivers that can insert many
+// patients within a single transaction implement it so callers like the
+// bulk-import endpoint avoid one round-trip per row. Callers should type
+// assert for it and fall back to repeated Create calls otherwise.
+type BulkCreator interface {
+	CreateMany(ctx context.Context, patients []Patient) ([]Patient, error)
+}